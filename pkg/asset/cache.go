@@ -0,0 +1,136 @@
+package asset
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// CachingStore memoizes an asset's rendered output against a
+// content-addressed key, typically derived from the bytes of its declared
+// dependencies. It lets a Generate implementation skip re-rendering when
+// none of its inputs have changed since the last run.
+type CachingStore interface {
+	// Hash returns a stable, content-addressed key for the given inputs,
+	// suitable for passing to Get and Put.
+	Hash(inputs ...[]byte) string
+
+	// Get returns the output previously stored under key, if any.
+	Get(key string) ([]byte, bool)
+
+	// Put stores output under key for future Get calls.
+	Put(key string, output []byte)
+}
+
+// memoryCachingStore is a CachingStore backed by an in-memory map keyed on
+// the SHA-256 of its inputs. It is safe for concurrent use.
+type memoryCachingStore struct {
+	mu    sync.RWMutex
+	cache map[string][]byte
+}
+
+// NewCachingStore returns a CachingStore backed by an in-memory,
+// content-addressed cache.
+func NewCachingStore() CachingStore {
+	return &memoryCachingStore{cache: make(map[string][]byte)}
+}
+
+// Hash implements CachingStore.
+func (s *memoryCachingStore) Hash(inputs ...[]byte) string {
+	h := sha256.New()
+	for _, input := range inputs {
+		h.Write(input)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Get implements CachingStore.
+func (s *memoryCachingStore) Get(key string) ([]byte, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	output, ok := s.cache[key]
+	return output, ok
+}
+
+// Put implements CachingStore.
+func (s *memoryCachingStore) Put(key string, output []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache[key] = output
+}
+
+// fileCachingStore is a CachingStore backed by a JSON file on disk. Unlike
+// memoryCachingStore, its cache survives between process invocations, so
+// that re-running `openshift-install create manifests` - a fresh process
+// each time - can still skip re-rendering assets whose dependencies are
+// unchanged from the previous run.
+type fileCachingStore struct {
+	path string
+
+	mu    sync.RWMutex
+	cache map[string]string // key -> base64-encoded output, for JSON storage
+}
+
+// NewFileCachingStore returns a CachingStore backed by the JSON file at
+// path, preloaded with whatever was persisted there by a previous run. A
+// missing file is treated as an empty cache; it is created on the first
+// Put.
+func NewFileCachingStore(path string) (CachingStore, error) {
+	s := &fileCachingStore{path: path, cache: make(map[string]string)}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read cache file %q", path)
+	}
+	if err := json.Unmarshal(data, &s.cache); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse cache file %q", path)
+	}
+	return s, nil
+}
+
+// Hash implements CachingStore.
+func (s *fileCachingStore) Hash(inputs ...[]byte) string {
+	h := sha256.New()
+	for _, input := range inputs {
+		h.Write(input)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Get implements CachingStore.
+func (s *fileCachingStore) Get(key string) ([]byte, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	encoded, ok := s.cache[key]
+	if !ok {
+		return nil, false
+	}
+	output, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, false
+	}
+	return output, true
+}
+
+// Put implements CachingStore. It persists the updated cache to disk
+// immediately, so a run that's interrupted after rendering some manifests
+// still benefits the next invocation.
+func (s *fileCachingStore) Put(key string, output []byte) {
+	s.mu.Lock()
+	s.cache[key] = base64.StdEncoding.EncodeToString(output)
+	data, err := json.MarshalIndent(s.cache, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return
+	}
+	_ = ioutil.WriteFile(s.path, data, 0644)
+}
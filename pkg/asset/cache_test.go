@@ -0,0 +1,70 @@
+package asset
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestMemoryCachingStoreRoundTrip(t *testing.T) {
+	store := NewCachingStore()
+
+	key := store.Hash([]byte("a"), []byte("b"))
+	if _, ok := store.Get(key); ok {
+		t.Fatal("expected a miss before anything was stored")
+	}
+
+	store.Put(key, []byte("output"))
+	output, ok := store.Get(key)
+	if !ok {
+		t.Fatal("expected a hit after Put")
+	}
+	if string(output) != "output" {
+		t.Errorf("expected %q, got %q", "output", output)
+	}
+}
+
+func TestMemoryCachingStoreHashIsStable(t *testing.T) {
+	store := NewCachingStore()
+
+	if store.Hash([]byte("a"), []byte("b")) != store.Hash([]byte("a"), []byte("b")) {
+		t.Error("expected identical inputs to hash identically")
+	}
+	if store.Hash([]byte("a"), []byte("b")) == store.Hash([]byte("a"), []byte("c")) {
+		t.Error("expected different inputs to hash differently")
+	}
+}
+
+func TestFileCachingStorePersistsAcrossInstances(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	first, err := NewFileCachingStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error creating cache: %v", err)
+	}
+	key := first.Hash([]byte("dependency-bytes"))
+	first.Put(key, []byte("rendered output"))
+
+	second, err := NewFileCachingStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error reopening cache: %v", err)
+	}
+	output, ok := second.Get(key)
+	if !ok {
+		t.Fatal("expected a cache hit loaded from a fresh instance backed by the same file")
+	}
+	if string(output) != "rendered output" {
+		t.Errorf("expected %q, got %q", "rendered output", output)
+	}
+}
+
+func TestFileCachingStoreMissingFileIsEmptyCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "does-not-exist.json")
+
+	store, err := NewFileCachingStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error for a missing cache file: %v", err)
+	}
+	if _, ok := store.Get(store.Hash([]byte("anything"))); ok {
+		t.Error("expected a miss from a cache backed by a nonexistent file")
+	}
+}
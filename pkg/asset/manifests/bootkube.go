@@ -0,0 +1,149 @@
+package manifests
+
+import (
+	"encoding/base64"
+	"path/filepath"
+
+	"github.com/openshift/installer/pkg/asset"
+	"github.com/openshift/installer/pkg/asset/ignition/machine"
+	"github.com/openshift/installer/pkg/asset/installconfig"
+	"github.com/openshift/installer/pkg/asset/kubeconfig"
+	"github.com/openshift/installer/pkg/asset/manifests/content/bootkube"
+	"github.com/openshift/installer/pkg/asset/manifests/etcd"
+	"github.com/openshift/installer/pkg/asset/tls"
+)
+
+func init() {
+	Register(&bootkubeManifestsProvider{})
+}
+
+// bootkubeManifestsProvider is the built-in ManifestProvider that renders
+// the bootkube templates and static manifests this installer has always
+// shipped. It exists as a ManifestProvider, rather than being wired directly
+// into Manifests.Generate, so that downstream providers are registered and
+// merged the same way.
+type bootkubeManifestsProvider struct{}
+
+// Dependencies returns the assets bootkubeManifestsProvider needs in order
+// to render its templates.
+func (p *bootkubeManifestsProvider) Dependencies() []asset.Asset {
+	return []asset.Asset{
+		&installconfig.InstallConfig{},
+		&tls.AggregatorCA{},
+		&tls.APIServerCertKey{},
+		&tls.APIServerProxyCertKey{},
+		&tls.ClusterAPIServerCertKey{},
+		&etcd.EtcdCertSigner{},
+		&tls.KubeCA{},
+		&tls.MCSCertKey{},
+		&tls.OpenshiftAPIServerCertKey{},
+		&kubeconfig.Admin{},
+		&OpenShiftConfig{},
+		&tls.RootCA{},
+		&tls.ServiceAccountKeyPair{},
+		&tls.ServiceServingCA{},
+		&machine.Worker{},
+	}
+}
+
+// GenerateManifests renders the bootkube templates and static manifests.
+func (p *bootkubeManifestsProvider) GenerateManifests(dependencies asset.Parents) ([]*asset.File, error) {
+	installConfig := &installconfig.InstallConfig{}
+	aggregatorCA := &tls.AggregatorCA{}
+	apiServerCertKey := &tls.APIServerCertKey{}
+	apiServerProxyCertKey := &tls.APIServerProxyCertKey{}
+	clusterAPIServerCertKey := &tls.ClusterAPIServerCertKey{}
+	etcdCertSigner := &etcd.EtcdCertSigner{}
+	kubeCA := &tls.KubeCA{}
+	mcsCertKey := &tls.MCSCertKey{}
+	openshiftAPIServerCertKey := &tls.OpenshiftAPIServerCertKey{}
+	adminKubeconfig := &kubeconfig.Admin{}
+	openShiftConfig := &OpenShiftConfig{}
+	rootCA := &tls.RootCA{}
+	serviceAccountKeyPair := &tls.ServiceAccountKeyPair{}
+	serviceServingCA := &tls.ServiceServingCA{}
+	workerIgnition := &machine.Worker{}
+	dependencies.Get(
+		installConfig,
+		aggregatorCA,
+		apiServerCertKey,
+		apiServerProxyCertKey,
+		clusterAPIServerCertKey,
+		etcdCertSigner,
+		kubeCA,
+		mcsCertKey,
+		openshiftAPIServerCertKey,
+		adminKubeconfig,
+		openShiftConfig,
+		rootCA,
+		serviceAccountKeyPair,
+		serviceServingCA,
+		workerIgnition,
+	)
+
+	templateData := &bootkubeTemplateData{
+		AggregatorCaCert:                base64.StdEncoding.EncodeToString(aggregatorCA.Cert()),
+		AggregatorCaKey:                 base64.StdEncoding.EncodeToString(aggregatorCA.Key()),
+		ApiserverCert:                   base64.StdEncoding.EncodeToString(apiServerCertKey.Cert()),
+		ApiserverKey:                    base64.StdEncoding.EncodeToString(apiServerCertKey.Key()),
+		ApiserverProxyCert:              base64.StdEncoding.EncodeToString(apiServerProxyCertKey.Cert()),
+		ApiserverProxyKey:               base64.StdEncoding.EncodeToString(apiServerProxyCertKey.Key()),
+		Base64encodeCloudProviderConfig: base64.StdEncoding.EncodeToString(openShiftConfig.CloudProviderConfig()),
+		ClusterapiCaCert:                base64.StdEncoding.EncodeToString(clusterAPIServerCertKey.Cert()),
+		ClusterapiCaKey:                 base64.StdEncoding.EncodeToString(clusterAPIServerCertKey.Key()),
+		EtcdCaCert:                      base64.StdEncoding.EncodeToString(etcdCertSigner.ServingCACert()),
+		EtcdClientCert:                  base64.StdEncoding.EncodeToString(etcdCertSigner.ClientCert()),
+		EtcdClientKey:                   base64.StdEncoding.EncodeToString(etcdCertSigner.ClientKey()),
+		KubeCaCert:                      base64.StdEncoding.EncodeToString(kubeCA.Cert()),
+		KubeCaKey:                       base64.StdEncoding.EncodeToString(kubeCA.Key()),
+		McsTLSCert:                      base64.StdEncoding.EncodeToString(mcsCertKey.Cert()),
+		McsTLSKey:                       base64.StdEncoding.EncodeToString(mcsCertKey.Key()),
+		OidcCaCert:                      base64.StdEncoding.EncodeToString(kubeCA.Cert()),
+		OpenshiftApiserverCert:          base64.StdEncoding.EncodeToString(openshiftAPIServerCertKey.Cert()),
+		OpenshiftApiserverKey:           base64.StdEncoding.EncodeToString(openshiftAPIServerCertKey.Key()),
+		OpenshiftLoopbackKubeconfig:     base64.StdEncoding.EncodeToString(adminKubeconfig.Files()[0].Data),
+		PullSecret:                      base64.StdEncoding.EncodeToString([]byte(installConfig.Config.PullSecret)),
+		RootCaCert:                      base64.StdEncoding.EncodeToString(rootCA.Cert()),
+		ServiceaccountKey:               base64.StdEncoding.EncodeToString(serviceAccountKeyPair.Private()),
+		ServiceaccountPub:               base64.StdEncoding.EncodeToString(serviceAccountKeyPair.Public()),
+		ServiceServingCaCert:            base64.StdEncoding.EncodeToString(serviceServingCA.Cert()),
+		ServiceServingCaKey:             base64.StdEncoding.EncodeToString(serviceServingCA.Key()),
+		TectonicNetworkOperatorImage:    "quay.io/coreos/tectonic-network-operator-dev:3b6952f5a1ba89bb32dd0630faddeaf2779c9a85",
+		WorkerIgnConfig:                 base64.StdEncoding.EncodeToString(workerIgnition.Files()[0].Data),
+		CVOClusterID:                    installConfig.Config.ClusterID,
+	}
+
+	assetData := map[string][]byte{
+		"cluster-apiserver-certs.yaml":          applyTemplateData(bootkube.ClusterApiserverCerts, templateData),
+		"ign-config.yaml":                       applyTemplateData(bootkube.IgnConfig, templateData),
+		"kube-apiserver-secret.yaml":            applyTemplateData(bootkube.KubeApiserverSecret, templateData),
+		"kube-cloud-config.yaml":                applyTemplateData(bootkube.KubeCloudConfig, templateData),
+		"kube-controller-manager-secret.yaml":   applyTemplateData(bootkube.KubeControllerManagerSecret, templateData),
+		"machine-config-server-tls-secret.yaml": applyTemplateData(bootkube.MachineConfigServerTLSSecret, templateData),
+		"openshift-apiserver-secret.yaml":       applyTemplateData(bootkube.OpenshiftApiserverSecret, templateData),
+		"pull.json":                             applyTemplateData(bootkube.Pull, templateData),
+		"tectonic-network-operator.yaml":        applyTemplateData(bootkube.TectonicNetworkOperator, templateData),
+		"cvo-overrides.yaml":                    applyTemplateData(bootkube.CVOOverrides, templateData),
+
+		"01-tectonic-namespace.yaml":                       []byte(bootkube.TectonicNamespace),
+		"02-ingress-namespace.yaml":                        []byte(bootkube.IngressNamespace),
+		"03-openshift-web-console-namespace.yaml":          []byte(bootkube.OpenshiftWebConsoleNamespace),
+		"04-openshift-machine-config-operator.yaml":        []byte(bootkube.OpenshiftMachineConfigOperator),
+		"05-openshift-cluster-api-namespace.yaml":          []byte(bootkube.OpenshiftClusterAPINamespace),
+		"app-version-kind.yaml":                            []byte(bootkube.AppVersionKind),
+		"app-version-mao.yaml":                             []byte(bootkube.AppVersionMao),
+		"app-version-tectonic-network.yaml":                []byte(bootkube.AppVersionTectonicNetwork),
+		"machine-config-operator-01-images-configmap.yaml": []byte(bootkube.MachineConfigOperator01ImagesConfigmap),
+		"operatorstatus-crd.yaml":                          []byte(bootkube.OperatorstatusCrd),
+	}
+
+	files := make([]*asset.File, 0, len(assetData))
+	for name, data := range assetData {
+		files = append(files, &asset.File{
+			Filename: filepath.Join(manifestDir, name),
+			Data:     data,
+		})
+	}
+
+	return files, nil
+}
@@ -0,0 +1,57 @@
+package manifests
+
+import (
+	"bytes"
+
+	"github.com/openshift/installer/pkg/asset"
+)
+
+// manifestCacheFile holds the content-addressed cache manifestCache
+// persists to, next to install-config.yaml. `openshift-install create
+// manifests` is a new process on every invocation, so the cache has to be
+// read back from disk on startup to do any good across runs.
+const manifestCacheFile = ".openshift_install_manifest_cache.json"
+
+// manifestCache is the CachingStore consulted by applyTemplateData and
+// Manifests.Generate so that re-running `openshift-install create
+// manifests` after a small install-config.yaml edit only re-renders the
+// manifests whose inputs actually changed.
+var manifestCache = newManifestCache()
+
+// newManifestCache loads manifestCacheFile, falling back to an empty
+// in-memory cache - which just makes every manifest look Changed on this
+// run - if the file can't be read or parsed, so a corrupt cache never
+// blocks manifest generation.
+func newManifestCache() asset.CachingStore {
+	store, err := asset.NewFileCachingStore(manifestCacheFile)
+	if err != nil {
+		return asset.NewCachingStore()
+	}
+	return store
+}
+
+// ChangeReport records which manifest files Manifests.Generate produced
+// with content different from what manifestCache held for them, versus
+// which came out identical. It is a report only: Generate still returns
+// every file from Files(), unchanged ones included.
+type ChangeReport struct {
+	Changed   []string
+	Unchanged []string
+}
+
+// diffFiles compares each file's data against manifestCache's record for
+// its filename, updates the cache, and returns a ChangeReport describing
+// what changed.
+func diffFiles(files []*asset.File) *ChangeReport {
+	report := &ChangeReport{}
+	for _, f := range files {
+		key := manifestCache.Hash([]byte(f.Filename))
+		if cached, ok := manifestCache.Get(key); ok && bytes.Equal(cached, f.Data) {
+			report.Unchanged = append(report.Unchanged, f.Filename)
+			continue
+		}
+		manifestCache.Put(key, f.Data)
+		report.Changed = append(report.Changed, f.Filename)
+	}
+	return report
+}
@@ -0,0 +1,67 @@
+package manifests
+
+import (
+	"testing"
+	"text/template"
+
+	"github.com/openshift/installer/pkg/asset"
+)
+
+func TestDiffFilesReportsChangedThenUnchanged(t *testing.T) {
+	// diffFiles consults the package-level manifestCache; swap in a fresh
+	// in-memory store for the duration of this test so it neither depends
+	// on, nor pollutes, manifestCacheFile on disk.
+	withMemoryManifestCache(t)
+
+	files := []*asset.File{
+		{Filename: "manifests/foo.yaml", Data: []byte("v1")},
+	}
+
+	first := diffFiles(files)
+	if len(first.Changed) != 1 || first.Changed[0] != "manifests/foo.yaml" {
+		t.Fatalf("expected foo.yaml to be reported Changed on first run, got %+v", first)
+	}
+	if len(first.Unchanged) != 0 {
+		t.Fatalf("expected nothing Unchanged on first run, got %+v", first)
+	}
+
+	second := diffFiles(files)
+	if len(second.Unchanged) != 1 || second.Unchanged[0] != "manifests/foo.yaml" {
+		t.Fatalf("expected foo.yaml to be reported Unchanged once its content repeats, got %+v", second)
+	}
+	if len(second.Changed) != 0 {
+		t.Fatalf("expected nothing Changed on the repeat run, got %+v", second)
+	}
+
+	files[0].Data = []byte("v2")
+	third := diffFiles(files)
+	if len(third.Changed) != 1 || third.Changed[0] != "manifests/foo.yaml" {
+		t.Fatalf("expected foo.yaml to be reported Changed again once its content differs, got %+v", third)
+	}
+}
+
+func TestApplyTemplateDataCachesRenderedOutput(t *testing.T) {
+	withMemoryManifestCache(t)
+
+	calls := 0
+	tmpl := template.Must(template.New("greeting").Funcs(template.FuncMap{
+		"count": func() int { calls++; return calls },
+	}).Parse(`{{.}}-{{count}}`))
+
+	first := applyTemplateData(tmpl, "hello")
+	second := applyTemplateData(tmpl, "hello")
+	if string(first) != string(second) {
+		t.Errorf("expected a repeat render with identical inputs to return the cached output, got %q then %q", first, second)
+	}
+	if calls != 1 {
+		t.Errorf("expected the template to execute exactly once across both calls, got %d executions", calls)
+	}
+
+	third := applyTemplateData(tmpl, "goodbye")
+	if string(third) == string(first) {
+		t.Error("expected a render with different template data to miss the cache")
+	}
+	if calls != 2 {
+		t.Errorf("expected the template to execute again for different input, got %d executions", calls)
+	}
+}
@@ -0,0 +1,25 @@
+package etcd
+
+import "github.com/openshift/installer/pkg/asset"
+
+// signerCacheFile holds the cached, serialized output of EtcdCertSigner's
+// RSA keygen and x509 signing, next to install-config.yaml. Signing a CA
+// plus every peer/signer/client certificate is the most expensive part of
+// `openshift-install create manifests`; caching it here means a re-run
+// with the same master count doesn't redo that work.
+const signerCacheFile = ".openshift_install_etcd_cache.json"
+
+// signerCache is the CachingStore EtcdCertSigner.sign consults before
+// re-issuing certificates.
+var signerCache = newSignerCache()
+
+// newSignerCache loads signerCacheFile, falling back to an empty
+// in-memory cache if it can't be read or parsed, so a corrupt cache file
+// never blocks certificate issuance.
+func newSignerCache() asset.CachingStore {
+	store, err := asset.NewFileCachingStore(signerCacheFile)
+	if err != nil {
+		return asset.NewCachingStore()
+	}
+	return store
+}
@@ -0,0 +1,96 @@
+package etcd
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+)
+
+func TestNewSelfSignedCAIsSelfSignedAndValid(t *testing.T) {
+	ca, err := newSelfSignedCA("test-ca")
+	if err != nil {
+		t.Fatalf("unexpected error generating CA: %v", err)
+	}
+
+	if !ca.cert.IsCA {
+		t.Error("expected the generated certificate to be a CA")
+	}
+	if ca.cert.Subject.CommonName != "test-ca" {
+		t.Errorf("expected common name %q, got %q", "test-ca", ca.cert.Subject.CommonName)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	if _, err := ca.cert.Verify(x509.VerifyOptions{Roots: pool, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}}); err != nil {
+		t.Errorf("expected the CA certificate to verify against itself: %v", err)
+	}
+}
+
+func TestCABundleSignChainsToCA(t *testing.T) {
+	ca, err := newSelfSignedCA("test-ca")
+	if err != nil {
+		t.Fatalf("unexpected error generating CA: %v", err)
+	}
+
+	certPEM, keyPEM, err := ca.sign("etcd-0", []string{"etcd-0.example.com"})
+	if err != nil {
+		t.Fatalf("unexpected error signing leaf certificate: %v", err)
+	}
+
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil {
+		t.Fatal("expected sign to return a PEM-encoded certificate")
+	}
+	leaf, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		t.Fatalf("failed to parse leaf certificate: %v", err)
+	}
+	if leaf.Subject.CommonName != "etcd-0" {
+		t.Errorf("expected common name %q, got %q", "etcd-0", leaf.Subject.CommonName)
+	}
+	if len(leaf.DNSNames) != 1 || leaf.DNSNames[0] != "etcd-0.example.com" {
+		t.Errorf("expected DNS SAN %q, got %v", "etcd-0.example.com", leaf.DNSNames)
+	}
+
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil {
+		t.Fatal("expected sign to return a PEM-encoded private key")
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		Roots:     pool,
+		DNSName:   "etcd-0.example.com",
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}); err != nil {
+		t.Errorf("expected the leaf certificate to chain to the CA: %v", err)
+	}
+}
+
+func TestCABundleSignRejectsWrongCA(t *testing.T) {
+	ca, err := newSelfSignedCA("test-ca")
+	if err != nil {
+		t.Fatalf("unexpected error generating CA: %v", err)
+	}
+	otherCA, err := newSelfSignedCA("other-ca")
+	if err != nil {
+		t.Fatalf("unexpected error generating other CA: %v", err)
+	}
+
+	certPEM, _, err := ca.sign("etcd-0", nil)
+	if err != nil {
+		t.Fatalf("unexpected error signing leaf certificate: %v", err)
+	}
+	certBlock, _ := pem.Decode(certPEM)
+	leaf, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		t.Fatalf("failed to parse leaf certificate: %v", err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(otherCA.cert)
+	if _, err := leaf.Verify(x509.VerifyOptions{Roots: pool, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}}); err == nil {
+		t.Error("expected verification against an unrelated CA to fail")
+	}
+}
@@ -0,0 +1,282 @@
+// Package etcd renders the etcd bootstrap certificate material and the
+// manifests that carry it, mirroring the cert-signer approach used by
+// cluster-etcd-operator: a single component owns the CAs and every leaf
+// certificate signed from them, so day-2 rotation can reuse the same code
+// path that first issued them.
+package etcd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/openshift/installer/pkg/asset"
+	"github.com/openshift/installer/pkg/asset/installconfig"
+)
+
+// peerCertKey is the cert/key pair issued to a single master's etcd peer.
+type peerCertKey struct {
+	node    string
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// EtcdCertSigner issues the etcd serving CA, metrics CA, signer, client, and
+// one peer cert/key pair per master, and renders them into manifest files.
+type EtcdCertSigner struct {
+	masterCount int
+
+	servingCA *caBundle
+	metricCA  *caBundle
+
+	signerCert, signerKey             []byte
+	metricSignerCert, metricSignerKey []byte
+	clientCert, clientKey             []byte
+	metricClientCert, metricClientKey []byte
+
+	peers []peerCertKey
+
+	files []*asset.File
+}
+
+var _ asset.WritableAsset = (*EtcdCertSigner)(nil)
+
+// Name returns a human friendly name for the asset.
+func (s *EtcdCertSigner) Name() string {
+	return "Etcd Certificate Signer"
+}
+
+// Dependencies returns the assets EtcdCertSigner needs, namely the install
+// config it reads the master count from.
+func (s *EtcdCertSigner) Dependencies() []asset.Asset {
+	return []asset.Asset{
+		&installconfig.InstallConfig{},
+	}
+}
+
+// Generate issues the serving CA, metrics CA, signer, client, and
+// per-master peer cert/key pairs, and renders them into manifest files.
+func (s *EtcdCertSigner) Generate(dependencies asset.Parents) error {
+	installConfig := &installconfig.InstallConfig{}
+	dependencies.Get(installConfig)
+
+	s.masterCount = installConfig.Config.MasterCount()
+
+	if err := s.sign(); err != nil {
+		return err
+	}
+
+	s.files = s.manifests()
+	return nil
+}
+
+// Rotate re-issues every certificate this signer owns, reusing the same
+// signing code path Generate uses. It is the hook day-2 rotation tooling
+// calls to refresh etcd's certificate material without re-deriving how many
+// peers there are or how the manifests are shaped. Unlike Generate, Rotate
+// always re-signs rather than consulting signerCache, since the entire
+// point of rotation is to stop using the previously issued certificates.
+func (s *EtcdCertSigner) Rotate() error {
+	if err := s.signFresh(); err != nil {
+		return err
+	}
+	s.files = s.manifests()
+	return nil
+}
+
+// ServingCACert returns the PEM-encoded etcd serving CA certificate, for
+// consumers such as the kube-apiserver that need to trust etcd.
+func (s *EtcdCertSigner) ServingCACert() []byte {
+	return s.servingCA.certPEM
+}
+
+// ClientCert returns the PEM-encoded certificate clients use to
+// authenticate to etcd.
+func (s *EtcdCertSigner) ClientCert() []byte {
+	return s.clientCert
+}
+
+// ClientKey returns the PEM-encoded private key paired with ClientCert.
+func (s *EtcdCertSigner) ClientKey() []byte {
+	return s.clientKey
+}
+
+// Files returns the files generated by the asset.
+func (s *EtcdCertSigner) Files() []*asset.File {
+	return s.files
+}
+
+// cachedPeer is the serializable form of peerCertKey; peerCertKey's own
+// fields are unexported and so would marshal to nothing.
+type cachedPeer struct {
+	Node    string
+	CertPEM []byte
+	KeyPEM  []byte
+}
+
+// cachedSigner is the serializable form of an EtcdCertSigner's signed
+// output, keyed in signerCache by the inputs sign used to produce it.
+type cachedSigner struct {
+	ServingCACertPEM, MetricCACertPEM []byte
+	SignerCert, SignerKey             []byte
+	MetricSignerCert, MetricSignerKey []byte
+	ClientCert, ClientKey             []byte
+	MetricClientCert, MetricClientKey []byte
+	Peers                             []cachedPeer
+}
+
+// cacheKey returns signerCache's key for this signer's current inputs -
+// today, just the master count, since that's all sign's output depends on.
+func (s *EtcdCertSigner) cacheKey() string {
+	return signerCache.Hash([]byte(fmt.Sprintf("master-count=%d", s.masterCount)))
+}
+
+// sign issues the serving CA, metrics CA, signer, client, and per-master
+// peer cert/key pairs, consulting signerCache first so a `create
+// manifests` re-run with an unchanged master count skips the RSA keygen
+// and x509 signing work entirely.
+func (s *EtcdCertSigner) sign() error {
+	if cached, ok := signerCache.Get(s.cacheKey()); ok {
+		if err := s.loadCached(cached); err == nil {
+			return nil
+		}
+		// A corrupt cache entry shouldn't block issuance; fall through
+		// and sign fresh certificates instead.
+	}
+	return s.signFresh()
+}
+
+// signFresh always re-issues every certificate this signer owns and
+// refreshes signerCache with the result, regardless of what was already
+// cached. Rotate calls this directly so rotation can never be served
+// stale certificates from the cache.
+func (s *EtcdCertSigner) signFresh() error {
+	servingCA, err := newSelfSignedCA("etcd-signer")
+	if err != nil {
+		return errors.Wrap(err, "failed to create etcd serving CA")
+	}
+	metricCA, err := newSelfSignedCA("etcd-metric-signer")
+	if err != nil {
+		return errors.Wrap(err, "failed to create etcd metrics CA")
+	}
+	s.servingCA = servingCA
+	s.metricCA = metricCA
+
+	if s.signerCert, s.signerKey, err = servingCA.sign("etcd-signer", nil); err != nil {
+		return errors.Wrap(err, "failed to sign etcd signer certificate")
+	}
+	if s.metricSignerCert, s.metricSignerKey, err = metricCA.sign("etcd-metric-signer", nil); err != nil {
+		return errors.Wrap(err, "failed to sign etcd metric signer certificate")
+	}
+	if s.clientCert, s.clientKey, err = servingCA.sign("etcd-client", nil); err != nil {
+		return errors.Wrap(err, "failed to sign etcd client certificate")
+	}
+	if s.metricClientCert, s.metricClientKey, err = metricCA.sign("etcd-metric-client", nil); err != nil {
+		return errors.Wrap(err, "failed to sign etcd metric client certificate")
+	}
+
+	s.peers = make([]peerCertKey, s.masterCount)
+	for i := 0; i < s.masterCount; i++ {
+		node := fmt.Sprintf("etcd-%d", i)
+		certPEM, keyPEM, err := servingCA.sign(node, []string{node})
+		if err != nil {
+			return errors.Wrapf(err, "failed to sign etcd peer certificate for %s", node)
+		}
+		s.peers[i] = peerCertKey{node: node, certPEM: certPEM, keyPEM: keyPEM}
+	}
+
+	peers := make([]cachedPeer, len(s.peers))
+	for i, p := range s.peers {
+		peers[i] = cachedPeer{Node: p.node, CertPEM: p.certPEM, KeyPEM: p.keyPEM}
+	}
+
+	output, err := json.Marshal(&cachedSigner{
+		ServingCACertPEM: s.servingCA.certPEM,
+		MetricCACertPEM:  s.metricCA.certPEM,
+		SignerCert:       s.signerCert,
+		SignerKey:        s.signerKey,
+		MetricSignerCert: s.metricSignerCert,
+		MetricSignerKey:  s.metricSignerKey,
+		ClientCert:       s.clientCert,
+		ClientKey:        s.clientKey,
+		MetricClientCert: s.metricClientCert,
+		MetricClientKey:  s.metricClientKey,
+		Peers:            peers,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to cache signed etcd certificates")
+	}
+	signerCache.Put(s.cacheKey(), output)
+
+	return nil
+}
+
+// loadCached restores a cachedSigner into s, so sign can serve a cache hit
+// without touching the CA's private key.
+func (s *EtcdCertSigner) loadCached(data []byte) error {
+	var cached cachedSigner
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return errors.Wrap(err, "failed to parse cached etcd certificates")
+	}
+
+	s.servingCA = &caBundle{certPEM: cached.ServingCACertPEM}
+	s.metricCA = &caBundle{certPEM: cached.MetricCACertPEM}
+	s.signerCert, s.signerKey = cached.SignerCert, cached.SignerKey
+	s.metricSignerCert, s.metricSignerKey = cached.MetricSignerCert, cached.MetricSignerKey
+	s.clientCert, s.clientKey = cached.ClientCert, cached.ClientKey
+	s.metricClientCert, s.metricClientKey = cached.MetricClientCert, cached.MetricClientKey
+
+	s.peers = make([]peerCertKey, len(cached.Peers))
+	for i, p := range cached.Peers {
+		s.peers[i] = peerCertKey{node: p.Node, certPEM: p.CertPEM, keyPEM: p.KeyPEM}
+	}
+	return nil
+}
+
+func (s *EtcdCertSigner) manifests() []*asset.File {
+	files := []*asset.File{
+		mustMarshal("etcd-serving-ca-configmap.yaml", configMapObject("etcd-serving-ca", map[string]string{
+			"ca-bundle.crt": string(s.servingCA.certPEM),
+		})),
+		mustMarshal("etcd-metric-serving-ca-configmap.yaml", configMapObject("etcd-metric-serving-ca", map[string]string{
+			"ca-bundle.crt": string(s.metricCA.certPEM),
+		})),
+		mustMarshal("etcd-signer-secret.yaml", secretObject("etcd-signer", map[string][]byte{
+			"tls.crt": s.signerCert,
+			"tls.key": s.signerKey,
+		})),
+		mustMarshal("etcd-metric-signer-secret.yaml", secretObject("etcd-metric-signer", map[string][]byte{
+			"tls.crt": s.metricSignerCert,
+			"tls.key": s.metricSignerKey,
+		})),
+		mustMarshal("etcd-client-secret.yaml", secretObject("etcd-client", map[string][]byte{
+			"tls.crt": s.clientCert,
+			"tls.key": s.clientKey,
+		})),
+		mustMarshal("etcd-metric-client-secret.yaml", secretObject("etcd-metric-client", map[string][]byte{
+			"tls.crt": s.metricClientCert,
+			"tls.key": s.metricClientKey,
+		})),
+	}
+
+	for _, peer := range s.peers {
+		files = append(files, mustMarshal(fmt.Sprintf("etcd-peer-%s-secret.yaml", peer.node), secretObject(fmt.Sprintf("etcd-peer-%s", peer.node), map[string][]byte{
+			"tls.crt": peer.certPEM,
+			"tls.key": peer.keyPEM,
+		})))
+	}
+
+	return files
+}
+
+// mustMarshal panics on failure, since obj is always one of the
+// map[string]interface{} literals built in this package and is guaranteed
+// to marshal.
+func mustMarshal(filename string, obj map[string]interface{}) *asset.File {
+	file, err := marshalManifest(filename, obj)
+	if err != nil {
+		panic(err)
+	}
+	return file
+}
@@ -0,0 +1,56 @@
+package etcd
+
+import (
+	"encoding/base64"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+
+	"github.com/openshift/installer/pkg/asset"
+)
+
+const (
+	manifestDir = "manifests"
+	namespace   = "kube-system"
+)
+
+func configMapObject(name string, data map[string]string) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": namespace,
+		},
+		"data": data,
+	}
+}
+
+func secretObject(name string, data map[string][]byte) map[string]interface{} {
+	encoded := make(map[string]string, len(data))
+	for k, v := range data {
+		encoded[k] = base64.StdEncoding.EncodeToString(v)
+	}
+	return map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"type":       "Opaque",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": namespace,
+		},
+		"data": encoded,
+	}
+}
+
+func marshalManifest(filename string, obj map[string]interface{}) (*asset.File, error) {
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal %s", filename)
+	}
+	return &asset.File{
+		Filename: filepath.Join(manifestDir, filename),
+		Data:     data,
+	}, nil
+}
@@ -0,0 +1,29 @@
+package manifests
+
+import (
+	"github.com/openshift/installer/pkg/asset"
+	"github.com/openshift/installer/pkg/asset/manifests/etcd"
+)
+
+func init() {
+	Register(&EtcdCertsProvider{})
+}
+
+// EtcdCertsProvider is the ManifestProvider that contributes the etcd
+// bootstrap certificate manifests rendered by the etcd cert-signer
+// subsystem (see pkg/asset/manifests/etcd).
+type EtcdCertsProvider struct{}
+
+// Dependencies returns the assets EtcdCertsProvider needs.
+func (p *EtcdCertsProvider) Dependencies() []asset.Asset {
+	return []asset.Asset{
+		&etcd.EtcdCertSigner{},
+	}
+}
+
+// GenerateManifests returns the etcd cert-signer's rendered manifest files.
+func (p *EtcdCertsProvider) GenerateManifests(dependencies asset.Parents) ([]*asset.File, error) {
+	signer := &etcd.EtcdCertSigner{}
+	dependencies.Get(signer)
+	return signer.Files(), nil
+}
@@ -0,0 +1,176 @@
+package manifests
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/openshift/installer/pkg/asset"
+	"github.com/openshift/installer/pkg/asset/installconfig"
+)
+
+// azureCloudProviderConfig is the JSON shape the Azure cloud provider reads
+// out of kube-controller-manager's cloud config, parallel to the
+// `openshift.json` an OpenShift-on-Azure orchestrator would otherwise have
+// to hand-write.
+type azureCloudProviderConfig struct {
+	Cloud           string `json:"cloud"`
+	TenantID        string `json:"tenantId"`
+	SubscriptionID  string `json:"subscriptionId"`
+	ResourceGroup   string `json:"resourceGroup"`
+	Location        string `json:"location"`
+	AADClientID     string `json:"aadClientId"`
+	AADClientSecret string `json:"aadClientSecret"`
+}
+
+// OpenShiftConfig renders the Azure cloud-provider config consumed by
+// kube-controller-manager and the cluster admin username/password pair
+// consumed by the OAuth server, for an Azure-hosted install. It is a
+// no-op - Files returns nothing and CloudProviderConfig returns nil - for
+// any other platform, since InstallConfig.Azure is only populated when
+// the install actually targets Azure.
+type OpenShiftConfig struct {
+	cloudProviderConfig []byte
+	htpasswd            []byte
+	files               []*asset.File
+}
+
+var _ asset.WritableAsset = (*OpenShiftConfig)(nil)
+
+// Name returns a human friendly name for the asset.
+func (c *OpenShiftConfig) Name() string {
+	return "OpenShift Cluster Config"
+}
+
+// Dependencies returns the assets OpenShiftConfig needs.
+func (c *OpenShiftConfig) Dependencies() []asset.Asset {
+	return []asset.Asset{
+		&installconfig.InstallConfig{},
+	}
+}
+
+// Generate populates the Azure cloud-provider config and the admin
+// htpasswd entry, and renders the htpasswd entry into the
+// openshift-cluster-config.yaml manifest; the cloud-provider config is not
+// part of that manifest and is exposed through CloudProviderConfig instead.
+// On any non-Azure platform, installConfig.Config.Azure is nil and Generate
+// leaves this asset empty rather than touching its fields.
+func (c *OpenShiftConfig) Generate(dependencies asset.Parents) error {
+	installConfig := &installconfig.InstallConfig{}
+	dependencies.Get(installConfig)
+
+	azure := installConfig.Config.Azure
+	if azure == nil {
+		return nil
+	}
+
+	cloudProviderConfig, htpasswd, err := buildOpenShiftConfig(&azureCloudProviderConfig{
+		Cloud:           "AzurePublicCloud",
+		TenantID:        azure.TenantID,
+		SubscriptionID:  azure.SubscriptionID,
+		ResourceGroup:   azure.ResourceGroup,
+		Location:        azure.Location,
+		AADClientID:     azure.AADClientID,
+		AADClientSecret: azure.AADClientSecret,
+	}, installConfig.Config.ClusterUsername, installConfig.Config.ClusterPassword)
+	if err != nil {
+		return errors.Wrap(err, "failed to build openshift-cluster-config inputs")
+	}
+	c.cloudProviderConfig = cloudProviderConfig
+	c.htpasswd = htpasswd
+
+	manifest, err := c.manifest()
+	if err != nil {
+		return errors.Wrap(err, "failed to create openshift-cluster-config manifest")
+	}
+
+	c.files = []*asset.File{
+		{
+			Filename: filepath.Join(manifestDir, "openshift-cluster-config.yaml"),
+			Data:     manifest,
+		},
+	}
+	return nil
+}
+
+// buildOpenShiftConfig JSON-encodes azure and bcrypt-hashes
+// clusterUsername/clusterPassword into an htpasswd entry. It is factored
+// out of Generate, which has to go through the installconfig.InstallConfig
+// dependency, so the field-mapping and hashing logic can be unit tested
+// directly. The bcrypt hash is cached in manifestCache, keyed on the
+// username/password themselves, since bcrypt is deliberately expensive and
+// the password doesn't change between most `create manifests` re-runs.
+func buildOpenShiftConfig(azure *azureCloudProviderConfig, clusterUsername, clusterPassword string) (cloudProviderConfig, htpasswd []byte, err error) {
+	cloudProviderConfig, err = json.Marshal(azure)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to marshal azure cloud provider config")
+	}
+
+	htpasswdKey := manifestCache.Hash([]byte("htpasswd"), []byte(clusterUsername), []byte(clusterPassword))
+	if cached, ok := manifestCache.Get(htpasswdKey); ok {
+		return cloudProviderConfig, cached, nil
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(clusterPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to hash cluster admin password")
+	}
+	htpasswd = []byte(fmt.Sprintf("%s:%s\n", clusterUsername, hash))
+	manifestCache.Put(htpasswdKey, htpasswd)
+
+	return cloudProviderConfig, htpasswd, nil
+}
+
+func (c *OpenShiftConfig) manifest() ([]byte, error) {
+	return yaml.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"type":       "Opaque",
+		"metadata": map[string]interface{}{
+			"name":      "openshift-cluster-config",
+			"namespace": "openshift-config",
+		},
+		"data": map[string]string{
+			"htpasswd": base64.StdEncoding.EncodeToString(c.htpasswd),
+		},
+	})
+}
+
+// CloudProviderConfig returns the JSON-encoded Azure cloud provider config
+// consumed by the kube-cloud-config.yaml bootkube template.
+func (c *OpenShiftConfig) CloudProviderConfig() []byte {
+	return c.cloudProviderConfig
+}
+
+// Files returns the files generated by the asset.
+func (c *OpenShiftConfig) Files() []*asset.File {
+	return c.files
+}
+
+func init() {
+	Register(&openShiftConfigProvider{})
+}
+
+// openShiftConfigProvider is the ManifestProvider that contributes the
+// openshift-cluster-config.yaml manifest rendered by OpenShiftConfig. It
+// is registered unconditionally, like every ManifestProvider, but
+// contributes no files on a non-Azure install since OpenShiftConfig.Files
+// is empty there.
+type openShiftConfigProvider struct{}
+
+func (p *openShiftConfigProvider) Dependencies() []asset.Asset {
+	return []asset.Asset{
+		&OpenShiftConfig{},
+	}
+}
+
+func (p *openShiftConfigProvider) GenerateManifests(dependencies asset.Parents) ([]*asset.File, error) {
+	cfg := &OpenShiftConfig{}
+	dependencies.Get(cfg)
+	return cfg.Files(), nil
+}
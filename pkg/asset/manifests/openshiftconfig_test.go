@@ -0,0 +1,130 @@
+package manifests
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/openshift/installer/pkg/asset"
+)
+
+func TestOpenShiftConfigManifest(t *testing.T) {
+	c := &OpenShiftConfig{htpasswd: []byte("admin:$2a$10$fakehash\n")}
+
+	data, err := c.manifest()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var obj map[string]interface{}
+	if err := yaml.Unmarshal(data, &obj); err != nil {
+		t.Fatalf("manifest did not produce valid YAML: %v", err)
+	}
+
+	if obj["kind"] != "Secret" {
+		t.Errorf("expected kind Secret, got %v", obj["kind"])
+	}
+	metadata, ok := obj["metadata"].(map[string]interface{})
+	if !ok || metadata["name"] != "openshift-cluster-config" {
+		t.Errorf("expected metadata.name openshift-cluster-config, got %v", obj["metadata"])
+	}
+
+	secretData, ok := obj["data"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected a data map, got %T", obj["data"])
+	}
+	encoded, ok := secretData["htpasswd"].(string)
+	if !ok {
+		t.Fatalf("expected data.htpasswd to be a string, got %T", secretData["htpasswd"])
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatalf("data.htpasswd was not valid base64: %v", err)
+	}
+	if string(decoded) != string(c.htpasswd) {
+		t.Errorf("expected decoded htpasswd %q, got %q", c.htpasswd, decoded)
+	}
+}
+
+func TestOpenShiftConfigCloudProviderConfig(t *testing.T) {
+	c := &OpenShiftConfig{cloudProviderConfig: []byte(`{"cloud":"AzurePublicCloud"}`)}
+
+	if string(c.CloudProviderConfig()) != `{"cloud":"AzurePublicCloud"}` {
+		t.Errorf("expected CloudProviderConfig to return what Generate stored, got %q", c.CloudProviderConfig())
+	}
+}
+
+// withMemoryManifestCache swaps manifestCache for a fresh in-memory store
+// for the duration of a test, so tests don't depend on, or pollute,
+// manifestCacheFile on disk.
+func withMemoryManifestCache(t *testing.T) {
+	t.Helper()
+	old := manifestCache
+	manifestCache = asset.NewCachingStore()
+	t.Cleanup(func() { manifestCache = old })
+}
+
+// TestBuildOpenShiftConfig drives the field-mapping and password-hashing
+// logic Generate delegates to - the part of Generate's work that doesn't
+// require a full installconfig.InstallConfig to exercise.
+func TestBuildOpenShiftConfig(t *testing.T) {
+	withMemoryManifestCache(t)
+
+	azure := &azureCloudProviderConfig{
+		Cloud:           "AzurePublicCloud",
+		TenantID:        "tenant-id",
+		SubscriptionID:  "subscription-id",
+		ResourceGroup:   "resource-group",
+		Location:        "centralus",
+		AADClientID:     "client-id",
+		AADClientSecret: "client-secret",
+	}
+
+	cloudProviderConfig, htpasswd, err := buildOpenShiftConfig(azure, "admin", "hunter2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var decoded azureCloudProviderConfig
+	if err := json.Unmarshal(cloudProviderConfig, &decoded); err != nil {
+		t.Fatalf("cloudProviderConfig was not valid JSON: %v", err)
+	}
+	if decoded != *azure {
+		t.Errorf("expected cloud provider config %+v, got %+v", azure, decoded)
+	}
+
+	parts := strings.SplitN(strings.TrimSpace(string(htpasswd)), ":", 2)
+	if len(parts) != 2 || parts[0] != "admin" {
+		t.Fatalf("expected htpasswd entry for user %q, got %q", "admin", htpasswd)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(parts[1]), []byte("hunter2")); err != nil {
+		t.Errorf("expected the htpasswd hash to verify against the original password: %v", err)
+	}
+}
+
+// TestBuildOpenShiftConfigCachesHash confirms a second call with the same
+// username/password reuses the cached bcrypt hash instead of generating a
+// fresh one - bcrypt salts its output, so two independently-generated
+// hashes for the same password never match byte-for-byte.
+func TestBuildOpenShiftConfigCachesHash(t *testing.T) {
+	withMemoryManifestCache(t)
+
+	azure := &azureCloudProviderConfig{}
+
+	_, first, err := buildOpenShiftConfig(azure, "admin", "hunter2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, second, err := buildOpenShiftConfig(azure, "admin", "hunter2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if string(first) != string(second) {
+		t.Errorf("expected the second call to reuse the cached htpasswd hash, got %q and %q", first, second)
+	}
+}
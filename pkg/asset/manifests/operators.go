@@ -3,7 +3,7 @@ package manifests
 
 import (
 	"bytes"
-	"encoding/base64"
+	"fmt"
 	"path/filepath"
 	"text/template"
 
@@ -11,11 +11,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/openshift/installer/pkg/asset"
-	"github.com/openshift/installer/pkg/asset/ignition/machine"
 	"github.com/openshift/installer/pkg/asset/installconfig"
-	"github.com/openshift/installer/pkg/asset/kubeconfig"
-	"github.com/openshift/installer/pkg/asset/manifests/content/bootkube"
-	"github.com/openshift/installer/pkg/asset/tls"
 )
 
 const (
@@ -27,6 +23,7 @@ type Manifests struct {
 	kubeSysConfig  *configurationObject
 	tectonicConfig *configurationObject
 	files          []*asset.File
+	changeReport   *ChangeReport
 }
 
 var _ asset.WritableAsset = (*Manifests)(nil)
@@ -39,32 +36,23 @@ func (m *Manifests) Name() string {
 }
 
 // Dependencies returns all of the dependencies directly needed by a
-// Manifests asset.
+// Manifests asset. This is the fixed set needed to build the kube-system and
+// tectonic-system cluster-config-v1 config maps, plus Tectonic and the
+// ManifestOverlay applied after the providers run; everything else is
+// contributed by registered ManifestProviders (see provider.go), so that
+// providers can declare their own dependencies without this list growing to
+// know about them.
 func (m *Manifests) Dependencies() []asset.Asset {
-	return []asset.Asset{
+	deps := []asset.Asset{
 		&installconfig.InstallConfig{},
 		&KubeCoreOperator{},
 		&networkOperator{},
 		&kubeAddonOperator{},
 		&machineAPIOperator{},
 		&Tectonic{},
-		&tls.RootCA{},
-		&tls.EtcdCA{},
-		&tls.IngressCertKey{},
-		&tls.KubeCA{},
-		&tls.AggregatorCA{},
-		&tls.ServiceServingCA{},
-		&tls.ClusterAPIServerCertKey{},
-		&tls.EtcdClientCertKey{},
-		&tls.APIServerCertKey{},
-		&tls.OpenshiftAPIServerCertKey{},
-		&tls.APIServerProxyCertKey{},
-		&tls.MCSCertKey{},
-		&tls.KubeletCertKey{},
-		&tls.ServiceAccountKeyPair{},
-		&kubeconfig.Admin{},
-		&machine.Worker{},
+		&ManifestOverlay{},
 	}
+	return append(deps, providerDependencies()...)
 }
 
 // Generate generates the respective operator config.yml files
@@ -74,7 +62,8 @@ func (m *Manifests) Generate(dependencies asset.Parents) error {
 	addon := &kubeAddonOperator{}
 	mao := &machineAPIOperator{}
 	installConfig := &installconfig.InstallConfig{}
-	dependencies.Get(kco, no, addon, mao, installConfig)
+	overlay := &ManifestOverlay{}
+	dependencies.Get(kco, no, addon, mao, installConfig, overlay)
 
 	// kco+no+mao go to kube-system config map
 	m.kubeSysConfig = configMap("kube-system", "cluster-config-v1", genericData{
@@ -107,7 +96,19 @@ func (m *Manifests) Generate(dependencies asset.Parents) error {
 			Data:     tectonicConfigData,
 		},
 	}
-	m.files = append(m.files, m.generateBootKubeManifests(dependencies)...)
+
+	providerFiles, err := generateProviderManifests(dependencies)
+	if err != nil {
+		return err
+	}
+	m.files = append(m.files, providerFiles...)
+
+	m.files, err = overlay.Apply(m.files)
+	if err != nil {
+		return errors.Wrap(err, "failed to merge user-supplied manifest overlay")
+	}
+
+	m.changeReport = diffFiles(m.files)
 
 	return nil
 }
@@ -117,111 +118,29 @@ func (m *Manifests) Files() []*asset.File {
 	return m.files
 }
 
-func (m *Manifests) generateBootKubeManifests(dependencies asset.Parents) []*asset.File {
-	installConfig := &installconfig.InstallConfig{}
-	aggregatorCA := &tls.AggregatorCA{}
-	apiServerCertKey := &tls.APIServerCertKey{}
-	apiServerProxyCertKey := &tls.APIServerProxyCertKey{}
-	clusterAPIServerCertKey := &tls.ClusterAPIServerCertKey{}
-	etcdCA := &tls.EtcdCA{}
-	etcdClientCertKey := &tls.EtcdClientCertKey{}
-	kubeCA := &tls.KubeCA{}
-	mcsCertKey := &tls.MCSCertKey{}
-	openshiftAPIServerCertKey := &tls.OpenshiftAPIServerCertKey{}
-	adminKubeconfig := &kubeconfig.Admin{}
-	rootCA := &tls.RootCA{}
-	serviceAccountKeyPair := &tls.ServiceAccountKeyPair{}
-	serviceServingCA := &tls.ServiceServingCA{}
-	workerIgnition := &machine.Worker{}
-	dependencies.Get(
-		installConfig,
-		aggregatorCA,
-		apiServerCertKey,
-		apiServerProxyCertKey,
-		clusterAPIServerCertKey,
-		etcdCA,
-		etcdClientCertKey,
-		kubeCA,
-		mcsCertKey,
-		openshiftAPIServerCertKey,
-		adminKubeconfig,
-		rootCA,
-		serviceAccountKeyPair,
-		serviceServingCA,
-		workerIgnition,
-	)
-
-	templateData := &bootkubeTemplateData{
-		AggregatorCaCert:                base64.StdEncoding.EncodeToString(aggregatorCA.Cert()),
-		AggregatorCaKey:                 base64.StdEncoding.EncodeToString(aggregatorCA.Key()),
-		ApiserverCert:                   base64.StdEncoding.EncodeToString(apiServerCertKey.Cert()),
-		ApiserverKey:                    base64.StdEncoding.EncodeToString(apiServerCertKey.Key()),
-		ApiserverProxyCert:              base64.StdEncoding.EncodeToString(apiServerProxyCertKey.Cert()),
-		ApiserverProxyKey:               base64.StdEncoding.EncodeToString(apiServerProxyCertKey.Key()),
-		Base64encodeCloudProviderConfig: "", // FIXME
-		ClusterapiCaCert:                base64.StdEncoding.EncodeToString(clusterAPIServerCertKey.Cert()),
-		ClusterapiCaKey:                 base64.StdEncoding.EncodeToString(clusterAPIServerCertKey.Key()),
-		EtcdCaCert:                      base64.StdEncoding.EncodeToString(etcdCA.Cert()),
-		EtcdClientCert:                  base64.StdEncoding.EncodeToString(etcdClientCertKey.Cert()),
-		EtcdClientKey:                   base64.StdEncoding.EncodeToString(etcdClientCertKey.Key()),
-		KubeCaCert:                      base64.StdEncoding.EncodeToString(kubeCA.Cert()),
-		KubeCaKey:                       base64.StdEncoding.EncodeToString(kubeCA.Key()),
-		McsTLSCert:                      base64.StdEncoding.EncodeToString(mcsCertKey.Cert()),
-		McsTLSKey:                       base64.StdEncoding.EncodeToString(mcsCertKey.Key()),
-		OidcCaCert:                      base64.StdEncoding.EncodeToString(kubeCA.Cert()),
-		OpenshiftApiserverCert:          base64.StdEncoding.EncodeToString(openshiftAPIServerCertKey.Cert()),
-		OpenshiftApiserverKey:           base64.StdEncoding.EncodeToString(openshiftAPIServerCertKey.Key()),
-		OpenshiftLoopbackKubeconfig:     base64.StdEncoding.EncodeToString(adminKubeconfig.Files()[0].Data),
-		PullSecret:                      base64.StdEncoding.EncodeToString([]byte(installConfig.Config.PullSecret)),
-		RootCaCert:                      base64.StdEncoding.EncodeToString(rootCA.Cert()),
-		ServiceaccountKey:               base64.StdEncoding.EncodeToString(serviceAccountKeyPair.Private()),
-		ServiceaccountPub:               base64.StdEncoding.EncodeToString(serviceAccountKeyPair.Public()),
-		ServiceServingCaCert:            base64.StdEncoding.EncodeToString(serviceServingCA.Cert()),
-		ServiceServingCaKey:             base64.StdEncoding.EncodeToString(serviceServingCA.Key()),
-		TectonicNetworkOperatorImage:    "quay.io/coreos/tectonic-network-operator-dev:3b6952f5a1ba89bb32dd0630faddeaf2779c9a85",
-		WorkerIgnConfig:                 base64.StdEncoding.EncodeToString(workerIgnition.Files()[0].Data),
-		CVOClusterID:                    installConfig.Config.ClusterID,
-	}
-
-	assetData := map[string][]byte{
-		"cluster-apiserver-certs.yaml":          applyTemplateData(bootkube.ClusterApiserverCerts, templateData),
-		"ign-config.yaml":                       applyTemplateData(bootkube.IgnConfig, templateData),
-		"kube-apiserver-secret.yaml":            applyTemplateData(bootkube.KubeApiserverSecret, templateData),
-		"kube-cloud-config.yaml":                applyTemplateData(bootkube.KubeCloudConfig, templateData),
-		"kube-controller-manager-secret.yaml":   applyTemplateData(bootkube.KubeControllerManagerSecret, templateData),
-		"machine-config-server-tls-secret.yaml": applyTemplateData(bootkube.MachineConfigServerTLSSecret, templateData),
-		"openshift-apiserver-secret.yaml":       applyTemplateData(bootkube.OpenshiftApiserverSecret, templateData),
-		"pull.json":                             applyTemplateData(bootkube.Pull, templateData),
-		"tectonic-network-operator.yaml":        applyTemplateData(bootkube.TectonicNetworkOperator, templateData),
-		"cvo-overrides.yaml":                    applyTemplateData(bootkube.CVOOverrides, templateData),
-
-		"01-tectonic-namespace.yaml":                       []byte(bootkube.TectonicNamespace),
-		"02-ingress-namespace.yaml":                        []byte(bootkube.IngressNamespace),
-		"03-openshift-web-console-namespace.yaml":          []byte(bootkube.OpenshiftWebConsoleNamespace),
-		"04-openshift-machine-config-operator.yaml":        []byte(bootkube.OpenshiftMachineConfigOperator),
-		"05-openshift-cluster-api-namespace.yaml":          []byte(bootkube.OpenshiftClusterAPINamespace),
-		"app-version-kind.yaml":                            []byte(bootkube.AppVersionKind),
-		"app-version-mao.yaml":                             []byte(bootkube.AppVersionMao),
-		"app-version-tectonic-network.yaml":                []byte(bootkube.AppVersionTectonicNetwork),
-		"machine-config-operator-01-images-configmap.yaml": []byte(bootkube.MachineConfigOperator01ImagesConfigmap),
-		"operatorstatus-crd.yaml":                          []byte(bootkube.OperatorstatusCrd),
-	}
-
-	files := make([]*asset.File, 0, len(assetData))
-	for name, data := range assetData {
-		files = append(files, &asset.File{
-			Filename: filepath.Join(manifestDir, name),
-			Data:     data,
-		})
-	}
-
-	return files
+// ChangeReport returns which manifests actually differed from the previous
+// Generate call's output, as tracked by manifestCache. It is nil until
+// Generate has run at least once.
+func (m *Manifests) ChangeReport() *ChangeReport {
+	return m.changeReport
 }
 
+// applyTemplateData renders template with templateData, skipping the
+// render in favor of manifestCache's stored output when an identical
+// (template, templateData) pair has already been rendered - the common
+// case when a user re-runs `openshift-install create manifests` after
+// tweaking a single unrelated install-config.yaml field.
 func applyTemplateData(template *template.Template, templateData interface{}) []byte {
+	key := manifestCache.Hash([]byte(template.Name()), []byte(fmt.Sprintf("%+v", templateData)))
+	if cached, ok := manifestCache.Get(key); ok {
+		return cached
+	}
+
 	buf := &bytes.Buffer{}
 	if err := template.Execute(buf, templateData); err != nil {
 		panic(err)
 	}
-	return buf.Bytes()
-}
\ No newline at end of file
+	output := buf.Bytes()
+	manifestCache.Put(key, output)
+	return output
+}
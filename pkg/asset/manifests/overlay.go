@@ -0,0 +1,213 @@
+package manifests
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+
+	"github.com/openshift/installer/pkg/asset"
+)
+
+const (
+	manifestsOverlayDir = "manifests"
+	openshiftOverlayDir = "openshift"
+	patchSuffix         = ".patch.yaml"
+)
+
+// ManifestOverlay reads user-supplied manifests and patches out of the
+// manifests/ and openshift/ directories next to install-config.yaml, so
+// Manifests.Generate can merge them into the generated manifest set as a
+// stage after its own templates have rendered. Plain files are appended as
+// new manifests; files whose name matches a generated manifest, or that
+// carry a .patch.yaml suffix naming one, are merged onto that manifest's
+// content. This is how users inject the extra namespaces, MachineConfigs,
+// and operator CRs that would otherwise require forking the installer.
+type ManifestOverlay struct {
+	files []*asset.File
+}
+
+var _ asset.Asset = (*ManifestOverlay)(nil)
+
+// Name returns a human friendly name for the asset.
+func (o *ManifestOverlay) Name() string {
+	return "User-supplied Manifest Overlay"
+}
+
+// Dependencies returns the assets ManifestOverlay needs.
+func (o *ManifestOverlay) Dependencies() []asset.Asset {
+	return []asset.Asset{}
+}
+
+// Generate reads every YAML file out of the manifests/ and openshift/
+// overlay directories, if present. Neither directory is required to exist.
+func (o *ManifestOverlay) Generate(dependencies asset.Parents) error {
+	o.files = nil
+	for _, dir := range []string{manifestsOverlayDir, openshiftOverlayDir} {
+		files, err := readOverlayDir(dir)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read manifest overlay directory %q", dir)
+		}
+		o.files = append(o.files, files...)
+	}
+	return nil
+}
+
+// readOverlayDir reads every .yaml/.yml file directly inside dir, returning
+// nil without error if dir does not exist.
+func readOverlayDir(dir string) ([]*asset.File, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*asset.File
+	for _, entry := range entries {
+		if entry.IsDir() || !isYAMLFile(entry.Name()) {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s", entry.Name())
+		}
+		files = append(files, &asset.File{
+			Filename: filepath.Join(dir, entry.Name()),
+			Data:     data,
+		})
+	}
+	return files, nil
+}
+
+func isYAMLFile(name string) bool {
+	return strings.HasSuffix(name, ".yaml") || strings.HasSuffix(name, ".yml")
+}
+
+// Apply merges the overlay's files into generated, returning the combined
+// manifest set. A plain overlay file that doesn't name a generated
+// manifest is appended as-is. An overlay file that shares its full
+// relative path with a generated manifest, or is named `<name>.patch.yaml`
+// for a generated `<name>.yaml`, is merged onto that manifest after
+// confirming the two share an apiVersion/kind; a `.patch.yaml` overlay
+// whose target Manifests never generated is rejected rather than silently
+// dropped.
+//
+// Matching is done on the full relative path - e.g. "manifests/cluster-
+// config.yaml" - rather than the base file name, because two generated
+// manifests can share a base name (the kube-system and tectonic-system
+// cluster-config.yaml configmaps, for instance) while living in different
+// output directories; basename matching would merge an overlay onto
+// whichever of them happened to be inserted last and leave the other
+// untouched.
+func (o *ManifestOverlay) Apply(generated []*asset.File) ([]*asset.File, error) {
+	byPath := make(map[string]*asset.File, len(generated))
+	for _, f := range generated {
+		byPath[f.Filename] = f
+	}
+
+	merged := append([]*asset.File{}, generated...)
+	for _, overlay := range o.files {
+		target, isPatch := overlayTarget(overlay.Filename)
+
+		existing, ok := byPath[target]
+		if !ok {
+			if isPatch {
+				return nil, errors.Errorf("manifest overlay %q patches %q, which was never generated", overlay.Filename, target)
+			}
+			merged = append(merged, overlay)
+			continue
+		}
+
+		mergedData, err := mergeManifest(existing.Data, overlay.Data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to merge manifest overlay %q onto %q", overlay.Filename, existing.Filename)
+		}
+		existing.Data = mergedData
+	}
+
+	return merged, nil
+}
+
+// overlayTarget returns the generated manifest's full relative path that
+// an overlay file targets, and whether that overlay came in as a
+// `.patch.yaml` rather than a same-named full manifest.
+func overlayTarget(overlayPath string) (target string, isPatch bool) {
+	if strings.HasSuffix(overlayPath, patchSuffix) {
+		return strings.TrimSuffix(overlayPath, patchSuffix) + ".yaml", true
+	}
+	return overlayPath, false
+}
+
+// manifestGVK is just enough of a Kubernetes object to read its
+// apiVersion/kind for validating a patch targets the right manifest.
+type manifestGVK struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+}
+
+func gvkOf(manifest []byte) (manifestGVK, error) {
+	var gvk manifestGVK
+	if err := yaml.Unmarshal(manifest, &gvk); err != nil {
+		return manifestGVK{}, errors.Wrap(err, "failed to read apiVersion/kind")
+	}
+	if gvk.Kind == "" {
+		return manifestGVK{}, errors.New("manifest is missing a kind")
+	}
+	return gvk, nil
+}
+
+// mergeManifest validates that original and patch describe the same
+// apiVersion/kind, then deep-merges patch's fields onto original, with
+// patch taking precedence at every leaf.
+func mergeManifest(original, patch []byte) ([]byte, error) {
+	originalGVK, err := gvkOf(original)
+	if err != nil {
+		return nil, errors.Wrap(err, "generated manifest")
+	}
+	patchGVK, err := gvkOf(patch)
+	if err != nil {
+		return nil, errors.Wrap(err, "manifest overlay")
+	}
+	if originalGVK != patchGVK {
+		return nil, errors.Errorf("overlay is %s/%s but generated manifest is %s/%s", patchGVK.APIVersion, patchGVK.Kind, originalGVK.APIVersion, originalGVK.Kind)
+	}
+
+	var originalObj, patchObj map[string]interface{}
+	if err := yaml.Unmarshal(original, &originalObj); err != nil {
+		return nil, errors.Wrap(err, "failed to parse generated manifest")
+	}
+	if err := yaml.Unmarshal(patch, &patchObj); err != nil {
+		return nil, errors.Wrap(err, "failed to parse manifest overlay")
+	}
+
+	return yaml.Marshal(mergeMaps(originalObj, patchObj))
+}
+
+// mergeMaps recursively merges patch onto original, with patch's scalar
+// and list values taking precedence and nested maps merged field-by-field.
+func mergeMaps(original, patch map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(original))
+	for k, v := range original {
+		merged[k] = v
+	}
+	for k, patchVal := range patch {
+		originalVal, ok := merged[k]
+		if !ok {
+			merged[k] = patchVal
+			continue
+		}
+		originalMap, originalIsMap := originalVal.(map[string]interface{})
+		patchMap, patchIsMap := patchVal.(map[string]interface{})
+		if originalIsMap && patchIsMap {
+			merged[k] = mergeMaps(originalMap, patchMap)
+			continue
+		}
+		merged[k] = patchVal
+	}
+	return merged
+}
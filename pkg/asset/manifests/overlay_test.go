@@ -0,0 +1,141 @@
+package manifests
+
+import (
+	"testing"
+
+	"github.com/ghodss/yaml"
+
+	"github.com/openshift/installer/pkg/asset"
+)
+
+func TestMergeMaps(t *testing.T) {
+	original := map[string]interface{}{
+		"a": "original",
+		"b": map[string]interface{}{
+			"c": "original",
+			"d": "original",
+		},
+	}
+	patch := map[string]interface{}{
+		"a": "patched",
+		"b": map[string]interface{}{
+			"c": "patched",
+		},
+		"e": "new",
+	}
+
+	merged := mergeMaps(original, patch)
+
+	if merged["a"] != "patched" {
+		t.Errorf("expected top-level scalar to be overwritten by patch, got %v", merged["a"])
+	}
+	if merged["e"] != "new" {
+		t.Errorf("expected new top-level key from patch, got %v", merged["e"])
+	}
+	nested, ok := merged["b"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected nested map to survive merge, got %T", merged["b"])
+	}
+	if nested["c"] != "patched" {
+		t.Errorf("expected nested key to be overwritten by patch, got %v", nested["c"])
+	}
+	if nested["d"] != "original" {
+		t.Errorf("expected nested key absent from patch to survive, got %v", nested["d"])
+	}
+}
+
+func TestMergeManifestRejectsMismatchedGVK(t *testing.T) {
+	original := []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: foo\n")
+	patch := []byte("apiVersion: v1\nkind: Secret\nmetadata:\n  name: foo\n")
+
+	if _, err := mergeManifest(original, patch); err == nil {
+		t.Fatal("expected an error merging a patch with a different apiVersion/kind")
+	}
+}
+
+func TestMergeManifestMergesData(t *testing.T) {
+	original := []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: foo\ndata:\n  existing: keep\n")
+	patch := []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: foo\ndata:\n  added: new\n")
+
+	merged, err := mergeManifest(original, patch)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	gvk, err := gvkOf(merged)
+	if err != nil {
+		t.Fatalf("merged manifest should still parse: %v", err)
+	}
+	if gvk.Kind != "ConfigMap" {
+		t.Errorf("expected merged manifest to keep its kind, got %q", gvk.Kind)
+	}
+
+	var obj map[string]interface{}
+	if err := yaml.Unmarshal(merged, &obj); err != nil {
+		t.Fatalf("failed to parse merged manifest: %v", err)
+	}
+	data, ok := obj["data"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected merged manifest to have a data map, got %T", obj["data"])
+	}
+	if data["existing"] != "keep" {
+		t.Errorf("expected original data key to survive, got %v", data["existing"])
+	}
+	if data["added"] != "new" {
+		t.Errorf("expected patch data key to be added, got %v", data["added"])
+	}
+}
+
+// TestApplyDoesNotConflateFilesWithTheSameBaseName guards against matching
+// overlay files by base name alone: Manifests.Generate emits both
+// manifests/cluster-config.yaml (kube-system) and tectonic/cluster-
+// config.yaml (tectonic-system), and an overlay for one must never be
+// merged onto the other.
+func TestApplyDoesNotConflateFilesWithTheSameBaseName(t *testing.T) {
+	generated := []*asset.File{
+		{Filename: "manifests/cluster-config.yaml", Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cluster-config-v1\n  namespace: kube-system\ndata:\n  kco-config: original\n")},
+		{Filename: "tectonic/cluster-config.yaml", Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cluster-config-v1\n  namespace: tectonic-system\ndata:\n  addon-config: original\n")},
+	}
+	overlay := &ManifestOverlay{
+		files: []*asset.File{
+			{Filename: "manifests/cluster-config.yaml", Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cluster-config-v1\n  namespace: kube-system\ndata:\n  kco-config: patched\n")},
+		},
+	}
+
+	merged, err := overlay.Apply(generated)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	byPath := make(map[string]*asset.File, len(merged))
+	for _, f := range merged {
+		byPath[f.Filename] = f
+	}
+
+	var kubeSys, tectonic map[string]interface{}
+	if err := yaml.Unmarshal(byPath["manifests/cluster-config.yaml"].Data, &kubeSys); err != nil {
+		t.Fatalf("failed to parse kube-system manifest: %v", err)
+	}
+	if err := yaml.Unmarshal(byPath["tectonic/cluster-config.yaml"].Data, &tectonic); err != nil {
+		t.Fatalf("failed to parse tectonic manifest: %v", err)
+	}
+
+	if kubeSys["data"].(map[string]interface{})["kco-config"] != "patched" {
+		t.Errorf("expected the targeted manifests/cluster-config.yaml to be patched")
+	}
+	if tectonic["data"].(map[string]interface{})["addon-config"] != "original" {
+		t.Errorf("expected tectonic/cluster-config.yaml to be left untouched, got %v", tectonic["data"])
+	}
+}
+
+func TestApplyRejectsPatchForUngeneratedManifest(t *testing.T) {
+	overlay := &ManifestOverlay{
+		files: []*asset.File{
+			{Filename: "manifests/does-not-exist.patch.yaml", Data: []byte("apiVersion: v1\nkind: ConfigMap\n")},
+		},
+	}
+
+	if _, err := overlay.Apply(nil); err == nil {
+		t.Fatal("expected an error patching a manifest that was never generated")
+	}
+}
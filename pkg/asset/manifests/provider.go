@@ -0,0 +1,79 @@
+package manifests
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/openshift/installer/pkg/asset"
+)
+
+// ManifestProvider is implemented by anything that contributes a set of
+// manifests to the generated cluster manifest set. Providers are registered
+// at init time via Register, which lets a downstream fork add its own
+// manifests - cloud-provider config maps, extra namespaces, alternate
+// network operators, and so on - without patching this package.
+type ManifestProvider interface {
+	// Dependencies returns the assets the provider needs in order to
+	// generate its manifests.
+	Dependencies() []asset.Asset
+
+	// GenerateManifests renders the provider's manifests from the given
+	// dependencies, which have already been resolved by the asset store.
+	GenerateManifests(dependencies asset.Parents) ([]*asset.File, error)
+}
+
+var providers []ManifestProvider
+
+// Register adds a ManifestProvider to the set consulted by
+// Manifests.Dependencies and Manifests.Generate. It is meant to be called
+// from a provider's init function, e.g.:
+//
+//	func init() {
+//		manifests.Register(&EtcdCertsProvider{})
+//	}
+func Register(p ManifestProvider) {
+	providers = append(providers, p)
+}
+
+// providerDependencies returns the union of every registered provider's
+// dependencies, de-duplicated by concrete type.
+func providerDependencies() []asset.Asset {
+	seen := make(map[string]bool)
+	deps := []asset.Asset{}
+	for _, p := range providers {
+		for _, dep := range p.Dependencies() {
+			key := fmt.Sprintf("%T", dep)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			deps = append(deps, dep)
+		}
+	}
+	return deps
+}
+
+// generateProviderManifests runs every registered provider and merges their
+// output files into a single slice. Two providers contributing the same
+// Filename is treated as a configuration error rather than one silently
+// overwriting the other, since providers are written independently of one
+// another and have no way to know what filenames their peers use.
+func generateProviderManifests(dependencies asset.Parents) ([]*asset.File, error) {
+	files := []*asset.File{}
+	seen := make(map[string]ManifestProvider)
+	for _, p := range providers {
+		providerFiles, err := p.GenerateManifests(dependencies)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to generate manifests for provider %T", p)
+		}
+		for _, f := range providerFiles {
+			if owner, ok := seen[f.Filename]; ok {
+				return nil, errors.Errorf("manifest providers %T and %T both generated %q", owner, p, f.Filename)
+			}
+			seen[f.Filename] = p
+		}
+		files = append(files, providerFiles...)
+	}
+	return files, nil
+}
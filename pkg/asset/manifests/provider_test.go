@@ -0,0 +1,94 @@
+package manifests
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/openshift/installer/pkg/asset"
+)
+
+// fakeProvider is a minimal ManifestProvider for exercising
+// generateProviderManifests and providerDependencies without needing real
+// assets.
+type fakeProvider struct {
+	deps  []asset.Asset
+	files []*asset.File
+	err   error
+}
+
+func (p *fakeProvider) Dependencies() []asset.Asset { return p.deps }
+
+func (p *fakeProvider) GenerateManifests(dependencies asset.Parents) ([]*asset.File, error) {
+	return p.files, p.err
+}
+
+func withProviders(t *testing.T, fakes ...ManifestProvider) {
+	t.Helper()
+	old := providers
+	providers = nil
+	for _, p := range fakes {
+		Register(p)
+	}
+	t.Cleanup(func() { providers = old })
+}
+
+func TestGenerateProviderManifestsMergesDistinctFiles(t *testing.T) {
+	withProviders(t,
+		&fakeProvider{files: []*asset.File{{Filename: "manifests/a.yaml", Data: []byte("a")}}},
+		&fakeProvider{files: []*asset.File{{Filename: "manifests/b.yaml", Data: []byte("b")}}},
+	)
+
+	files, err := generateProviderManifests(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected 2 merged files, got %d", len(files))
+	}
+}
+
+func TestGenerateProviderManifestsRejectsFilenameCollision(t *testing.T) {
+	withProviders(t,
+		&fakeProvider{files: []*asset.File{{Filename: "manifests/a.yaml", Data: []byte("first")}}},
+		&fakeProvider{files: []*asset.File{{Filename: "manifests/a.yaml", Data: []byte("second")}}},
+	)
+
+	_, err := generateProviderManifests(nil)
+	if err == nil {
+		t.Fatal("expected an error when two providers generate the same filename")
+	}
+	if !strings.Contains(err.Error(), "manifests/a.yaml") {
+		t.Errorf("expected the error to name the conflicting filename, got: %v", err)
+	}
+}
+
+func TestGenerateProviderManifestsPropagatesProviderError(t *testing.T) {
+	withProviders(t, &fakeProvider{err: errTest})
+
+	if _, err := generateProviderManifests(nil); err == nil {
+		t.Fatal("expected a provider's error to propagate")
+	}
+}
+
+func TestProviderDependenciesDedupesByType(t *testing.T) {
+	withProviders(t,
+		&fakeProvider{deps: []asset.Asset{&fakeAsset{}}},
+		&fakeProvider{deps: []asset.Asset{&fakeAsset{}}},
+	)
+
+	deps := providerDependencies()
+	if len(deps) != 1 {
+		t.Fatalf("expected duplicate dependency types to be deduplicated, got %d deps", len(deps))
+	}
+}
+
+// fakeAsset is a minimal asset.Asset used only to give providerDependencies
+// something concrete to deduplicate by type.
+type fakeAsset struct{}
+
+func (a *fakeAsset) Name() string                             { return "fake" }
+func (a *fakeAsset) Dependencies() []asset.Asset               { return nil }
+func (a *fakeAsset) Generate(dependencies asset.Parents) error { return nil }
+
+var errTest = errors.New("fake provider error")